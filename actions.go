@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	repo "github.com/cli/go-gh/pkg/repository"
+)
+
+// parseActionRef splits an `owner/repo[/path]@ref` action reference into
+// its components.
+func parseActionRef(ref string) (owner, name, path, refName string, err error) {
+	t := strings.SplitN(ref, "@", 2)
+	if len(t) != 2 {
+		return "", "", "", "", fmt.Errorf("invalid action reference %q: missing @ref", ref)
+	}
+	refName = t[1]
+
+	parts := strings.Split(t[0], "/")
+	if len(parts) < 2 {
+		return "", "", "", "", fmt.Errorf("invalid action reference %q", ref)
+	}
+
+	owner, name = parts[0], parts[1]
+	path = strings.Join(parts[2:], "/")
+	return owner, name, path, refName, nil
+}
+
+// resolveSHA resolves ref (a branch, tag, or SHA) to the commit SHA it
+// currently points at.
+func resolveSHA(client api.RESTClient, actionRepo repo.Repository, ref string) (string, error) {
+	type commit struct {
+		SHA string `json:"sha"`
+	}
+
+	var result commit
+	path := fmt.Sprintf("repos/%s/%s/commits/%s", actionRepo.Owner(), actionRepo.Name(), url.PathEscape(ref))
+	if err := client.Get(path, &result); err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", actionRepo.Name(), ref, err)
+	}
+
+	return result.SHA, nil
+}
+
+// fetchActionYAML fetches action.yml (or action.yaml) for the action living
+// at path in actionRepo at ref.
+func fetchActionYAML(client api.RESTClient, actionRepo repo.Repository, path string, ref string) (string, error) {
+	var lastErr error
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		filePath := name
+		if path != "" {
+			filePath = path + "/" + name
+		}
+
+		content, err := getWorkflowContent(client, actionRepo, filePath, ref)
+		if err == nil {
+			return string(content), nil
+		}
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("failed to fetch action.yml for %s@%s: %w", path, ref, lastErr)
+}
+
+// resolveActionRef fetches the pinned SHA and action.yml/action.yaml content
+// for a single `uses:` reference, when possible. Docker refs are left
+// unresolved. localRef is the ref (branch/sha) the calling workflow itself
+// was checked out at, used to resolve local action references.
+func resolveActionRef(client api.RESTClient, baseRepo repo.Repository, ref string, kind RefKind, localRef string) (sha string, actionYAML string, err error) {
+	switch kind {
+	case RefKindDockerAction:
+		return "", "", nil
+	case RefKindLocalAction:
+		path := strings.TrimPrefix(strings.TrimPrefix(ref, "./"), "../")
+		content, err := fetchActionYAML(client, baseRepo, path, localRef)
+		return "", content, err
+	default:
+		owner, name, path, refName, err := parseActionRef(ref)
+		if err != nil {
+			return "", "", err
+		}
+
+		actionRepo, err := repo.Parse(fmt.Sprintf("%s/%s", owner, name))
+		if err != nil {
+			return "", "", err
+		}
+
+		sha, err = resolveSHA(client, actionRepo, refName)
+		if err != nil {
+			return "", "", err
+		}
+
+		content, err := fetchActionYAML(client, actionRepo, path, refName)
+		if err != nil {
+			return "", "", err
+		}
+
+		return sha, content, nil
+	}
+}
+
+// visitKey builds the identity a reusable workflow is tracked under while
+// walking the reference graph: the repo it lives in, the path within that
+// repo, and (when known) the commit SHA it resolves to. Two different
+// `uses:` strings that end up pointing at the same repo/path/sha must not be
+// fetched twice.
+func visitKey(repoNWO, path, sha string) string {
+	if sha != "" {
+		return repoNWO + "|" + path + "|" + sha
+	}
+	return repoNWO + "|" + path
+}
+
+// refPathOnly strips the `@ref` suffix and any `./`/`../` prefix from a
+// `uses:` value, leaving just the path portion.
+func refPathOnly(ref string) string {
+	path := ref
+	if i := strings.Index(ref, "@"); i >= 0 {
+		path = ref[:i]
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(path, "./"), "../")
+}
+
+// resolveRefIdentity determines the {repo, path, sha} a reusable-workflow
+// `uses:` value resolves to, so it can be matched against visitKey. sourceRepo
+// and containerSHA describe the workflow the reference was found in: a
+// `./`-relative ref resolves against sourceRepo at containerSHA, since that's
+// the repo the reference actually lives in (which may not be baseRepo, if
+// the reference was found inside a workflow fetched from another repo).
+//
+// path is returned in the same shape Workflow.RefPath uses for each case, so
+// it lines up with the visited-set seed built from already-fetched
+// Workflows: repo-relative for local refs (matching a `./`-relative
+// RefPath), but owner/repo-prefixed for remote refs (matching the
+// `owner/repo/path@ref` RefPath a remote Workflow is recorded under).
+func resolveRefIdentity(client api.RESTClient, sourceRepo repo.Repository, ref string, containerSHA string) (repoNWO, path, sha string, err error) {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		return fmt.Sprintf("%s/%s", sourceRepo.Owner(), sourceRepo.Name()), refPathOnly(ref), containerSHA, nil
+	}
+
+	owner, name, path, refName, err := parseActionRef(ref)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	nwo := fmt.Sprintf("%s/%s", owner, name)
+	actionRepo, err := repo.Parse(nwo)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	sha, err = resolveSHA(client, actionRepo, refName)
+	if err != nil {
+		// Fall back to an unresolved SHA rather than failing the walk; the
+		// key degrades to repo+path, which still prevents re-fetching the
+		// exact same ref string.
+		sha = ""
+	}
+
+	return nwo, nwo + "/" + path, sha, nil
+}
+
+// fetchReferencedWorkflow fetches the content of a reusable workflow
+// referenced via `uses:`, be it local to sourceRepo (`./path@ref` or
+// `./path`) or in another repo (`owner/repo/path@ref`). sourceRepo must be
+// the repo that contains the workflow the `uses:` line was found in — a
+// `./`-relative reference is resolved against it, not necessarily the run's
+// base repo. defaultRef is used for local references, which don't carry
+// their own `@ref`.
+func fetchReferencedWorkflow(client api.RESTClient, sourceRepo repo.Repository, defaultRef string, ref string) (*Workflow, error) {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		path := ref
+		refName := defaultRef
+		if i := strings.Index(ref, "@"); i >= 0 {
+			path = ref[:i]
+			refName = ref[i+1:]
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "./"), "../")
+
+		content, err := getWorkflowContent(client, sourceRepo, path, refName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workflow content: %w", err)
+		}
+
+		return &Workflow{
+			Name:     filepath.Base(path),
+			Repo:     fmt.Sprintf("%s/%s", sourceRepo.Owner(), sourceRepo.Name()),
+			RefPath:  ref,
+			Filename: filepath.Base(path),
+			Ref:      refName,
+			YAML:     string(content),
+		}, nil
+	}
+
+	owner, name, path, refName, err := parseActionRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	referencedRepo, err := repo.Parse(fmt.Sprintf("%s/%s", owner, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse referenced repo: %w", err)
+	}
+
+	content, err := getWorkflowContent(client, referencedRepo, path, refName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow content: %w", err)
+	}
+
+	sha, err := resolveSHA(client, referencedRepo, refName)
+	if err != nil {
+		// A resolvable SHA is nice-to-have here, not required to display
+		// the workflow, so don't fail the whole walk over it.
+		sha = ""
+	}
+
+	return &Workflow{
+		Name:     filepath.Base(path),
+		Repo:     fmt.Sprintf("%s/%s", owner, name),
+		RefPath:  ref,
+		Filename: filepath.Base(path),
+		Ref:      refName,
+		SHA:      sha,
+		YAML:     string(content),
+	}, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/cli/v2/pkg/cmd/factory"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/cli/go-gh"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+type DiffOptions struct {
+	RunA string
+	RunB string
+
+	MaxDepth int
+
+	IO *iostreams.IOStreams
+}
+
+func NewCmdDiff() *cobra.Command {
+	f := factory.New("1.0.0") // TODO: version
+
+	opts := &DiffOptions{IO: f.IOStreams}
+
+	cmd := &cobra.Command{
+		Use:   "diff <run-id-a> <run-id-b>",
+		Short: "Compare the effective workflow between two runs",
+		Args:  cobra.ExactArgs(2),
+		Example: heredoc.Doc(`
+			# See exactly what changed between two runs of the same workflow
+			$ gh effective-workflow diff 12345 12399
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.RunA = args[0]
+			opts.RunB = args[1]
+
+			return runDiff(opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", 5, "Maximum depth to recurse into nested reusable workflows")
+
+	return cmd
+}
+
+func runDiff(opts *DiffOptions) error {
+	baseRepo, err := gh.CurrentRepository()
+	if err != nil {
+		return fmt.Errorf("failed to determine base repo: %w", err)
+	}
+
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	a, err := fetchEffectiveWorkflow(opts.IO, client, baseRepo, opts.RunA, opts.MaxDepth)
+	if err != nil {
+		return fmt.Errorf("failed to fetch run %s: %w", opts.RunA, err)
+	}
+
+	b, err := fetchEffectiveWorkflow(opts.IO, client, baseRepo, opts.RunB, opts.MaxDepth)
+	if err != nil {
+		return fmt.Errorf("failed to fetch run %s: %w", opts.RunB, err)
+	}
+
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	aFiles := indexByRefPath(a.AllWorkflows)
+	bFiles := indexByRefPath(b.AllWorkflows)
+
+	aPaths := sortedKeys(aFiles)
+	bPaths := sortedKeys(bFiles)
+
+	for _, path := range aPaths {
+		wfA := aFiles[path]
+
+		wfB, ok := bFiles[path]
+		if !ok {
+			fmt.Fprintln(out, cs.Yellow(fmt.Sprintf("only in run %s: %s", opts.RunA, path)))
+			continue
+		}
+
+		if wfA.YAML == wfB.YAML {
+			continue
+		}
+
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(wfA.YAML),
+			B:        difflib.SplitLines(wfB.YAML),
+			FromFile: fmt.Sprintf("%s@run-%s", path, opts.RunA),
+			ToFile:   fmt.Sprintf("%s@run-%s", path, opts.RunB),
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to diff %s: %w", path, err)
+		}
+
+		fmt.Fprintln(out, cs.CyanBold(path))
+		fmt.Fprintln(out, text)
+	}
+
+	for _, path := range bPaths {
+		if _, ok := aFiles[path]; !ok {
+			fmt.Fprintln(out, cs.Yellow(fmt.Sprintf("only in run %s: %s", opts.RunB, path)))
+		}
+	}
+
+	fmt.Fprintln(out, cs.CyanBold("\nReferenced workflow changes"))
+	for _, path := range aPaths {
+		wfA := aFiles[path]
+
+		wfB, ok := bFiles[path]
+		if !ok || wfA.SHA == wfB.SHA {
+			continue
+		}
+
+		fmt.Fprintf(out, "%s: %s@%s -> %s@%s\n", wfA.Name, wfA.Ref, wfA.SHA, wfB.Ref, wfB.SHA)
+	}
+
+	aActions := indexActionsByIdentity(a.References)
+	bActions := indexActionsByIdentity(b.References)
+
+	fmt.Fprintln(out, cs.CyanBold("\nReferenced action changes"))
+	for _, identity := range sortedActionIdentities(aActions, bActions) {
+		refA, ok := aActions[identity]
+		if !ok {
+			continue
+		}
+
+		refB, ok := bActions[identity]
+		if !ok || (refA.ref == refB.ref && refA.sha == refB.sha) {
+			continue
+		}
+
+		fmt.Fprintf(out, "%s: %s -> %s\n", identity, describeActionRef(refA), describeActionRef(refB))
+	}
+
+	return nil
+}
+
+// actionRef is the pinned version and resolved SHA an action reference
+// points at in a single run.
+type actionRef struct {
+	ref string
+	sha string
+}
+
+// indexActionsByIdentity maps marketplace/local action references to the
+// version/SHA they're pinned to in this run, keyed by the action's identity
+// (its `uses:` value with the `@ref` pin stripped), so the same action can
+// be matched up across two runs even when the pinned version changed. When
+// the same action is pinned to more than one version within a single run,
+// the alphabetically first `uses:` value wins, so the result stays stable
+// across repeat invocations rather than depending on Go's map iteration
+// order.
+func indexActionsByIdentity(refs map[string][]Reference) map[string]actionRef {
+	result := make(map[string]actionRef)
+
+	tos := make([]string, 0, len(refs))
+	for to := range refs {
+		tos = append(tos, to)
+	}
+	sort.Strings(tos)
+
+	for _, to := range tos {
+		entries := refs[to]
+		if len(entries) == 0 {
+			continue
+		}
+
+		kind := entries[0].Kind
+		if kind != RefKindMarketplaceAction && kind != RefKindLocalAction {
+			continue
+		}
+
+		identity := actionIdentity(to)
+		if _, ok := result[identity]; ok {
+			continue
+		}
+		result[identity] = actionRef{ref: to, sha: entries[0].ResolvedSHA}
+	}
+
+	return result
+}
+
+// actionIdentity strips the `@ref` pin off a `uses:` value, leaving the
+// action identity (e.g. "actions/checkout") two differently-pinned
+// references can be matched up by.
+func actionIdentity(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i]
+	}
+	return ref
+}
+
+// describeActionRef renders the version an action is pinned to, plus its
+// resolved SHA when known.
+func describeActionRef(ar actionRef) string {
+	version := ar.ref
+	if i := strings.Index(ar.ref, "@"); i >= 0 {
+		version = ar.ref[i+1:]
+	}
+
+	if ar.sha != "" {
+		return fmt.Sprintf("%s@%s", version, ar.sha)
+	}
+	return version
+}
+
+// sortedActionIdentities returns the union of a's and b's keys in a stable,
+// deterministic order.
+func sortedActionIdentities(a, b map[string]actionRef) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+
+	for k := range a {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedKeys returns the keys of a workflow-by-path map in a stable,
+// deterministic order so diff output doesn't vary between runs.
+func sortedKeys(m map[string]Workflow) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// indexByRefPath keys workflows by the `uses:` path they're reachable at,
+// so the same reusable workflow can be matched up across two runs.
+func indexByRefPath(wfs []Workflow) map[string]Workflow {
+	result := make(map[string]Workflow, len(wfs))
+	for _, wf := range wfs {
+		result[wf.RefPath] = wf
+	}
+
+	return result
+}
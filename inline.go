@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/cli/v2/pkg/cmd/factory"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/cli/go-gh"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type InlineOptions struct {
+	RunID      string
+	OutputFile string
+	MaxDepth   int
+
+	IO *iostreams.IOStreams
+}
+
+func NewCmdInline() *cobra.Command {
+	f := factory.New("1.0.0") // TODO: version
+
+	opts := &InlineOptions{IO: f.IOStreams}
+
+	cmd := &cobra.Command{
+		Use:   "inline <run-id>",
+		Short: "Flatten a run's effective workflow into a single self-contained YAML document",
+		Args:  cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			# Archive the exact recipe of a historical run
+			$ gh effective-workflow inline 12345 -o recipe.yml
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.RunID = args[0]
+
+			return runInline(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Write the inlined workflow to a file instead of stdout")
+	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", 5, "Maximum depth to recurse into nested reusable workflows")
+
+	return cmd
+}
+
+func runInline(opts *InlineOptions) error {
+	baseRepo, err := gh.CurrentRepository()
+	if err != nil {
+		return fmt.Errorf("failed to determine base repo: %w", err)
+	}
+
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	result, err := fetchEffectiveWorkflow(opts.IO, client, baseRepo, opts.RunID, opts.MaxDepth)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(result.CallingWorkflow.YAML), &doc); err != nil {
+		return fmt.Errorf("failed to unmarshal calling workflow: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("empty workflow file")
+	}
+	root := doc.Content[0]
+
+	jobsNode := mappingValue(root, "jobs")
+	if jobsNode != nil && jobsNode.Kind == yaml.MappingNode {
+		// Inlined jobs can themselves call reusable workflows, so repeat
+		// until nothing is left to inline or --max-depth is reached.
+		byRefPath := indexByRefPath(result.Workflows)
+		for depth := 0; depth < opts.MaxDepth; depth++ {
+			inlined, err := inlineReusableJobs(jobsNode, byRefPath)
+			if err != nil {
+				return fmt.Errorf("failed to inline reusable workflow jobs: %w", err)
+			}
+			if !inlined {
+				break
+			}
+		}
+
+		inlineCompositeSteps(jobsNode, result.References)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal inlined workflow: %w", err)
+	}
+
+	if opts.OutputFile != "" {
+		if err := os.WriteFile(opts.OutputFile, out, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.OutputFile, err)
+		}
+
+		fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().Green(fmt.Sprintf("Wrote inlined workflow to %s", opts.OutputFile)))
+		return nil
+	}
+
+	_, err = opts.IO.Out.Write(out)
+	return err
+}
+
+// inlineReusableJobs replaces every `jobs.<id>.uses:` call to a known
+// reusable workflow with that workflow's own jobs, namespaced as
+// `<id>__<refID>` to avoid collisions, with `with:`/`secrets:` translated
+// into `env:` on the inlined jobs. A source-mapping comment is attached to
+// the first inlined job so the origin of the block stays traceable.
+//
+// The caller job's own `needs:`/`if:` describe when the *call* was allowed
+// to run, so they're carried onto whichever inlined jobs have no
+// dependency of their own within the reusable workflow (its entrypoints) —
+// otherwise those jobs would start running with none of the caller's
+// constraints. `needs:` values inside the reusable workflow are namespaced
+// the same way its job IDs are, and any sibling job elsewhere in the outer
+// workflow that had `needs: <caller-job-id>` is remapped to the namespaced
+// entrypoint(s) once every job has been inlined. It reports whether any
+// call was inlined.
+func inlineReusableJobs(jobsNode *yaml.Node, byRefPath map[string]Workflow) (bool, error) {
+	newContent := make([]*yaml.Node, 0, len(jobsNode.Content))
+
+	// entrypoints maps an original caller job ID to the namespaced job
+	// ID(s) that took its place, i.e. the inlined jobs with no dependency
+	// of their own within the reusable workflow.
+	entrypoints := make(map[string][]string)
+
+	for i := 0; i+1 < len(jobsNode.Content); i += 2 {
+		keyNode := jobsNode.Content[i]
+		valNode := jobsNode.Content[i+1]
+
+		usesNode := mappingValue(valNode, "uses")
+		if usesNode == nil || usesNode.Kind != yaml.ScalarNode {
+			newContent = append(newContent, keyNode, valNode)
+			continue
+		}
+
+		refWF, ok := byRefPath[usesNode.Value]
+		if !ok {
+			// Unresolved reference; leave the call as-is rather than aborting.
+			newContent = append(newContent, keyNode, valNode)
+			continue
+		}
+
+		var refDoc yaml.Node
+		if err := yaml.Unmarshal([]byte(refWF.YAML), &refDoc); err != nil {
+			return false, fmt.Errorf("failed to unmarshal %s: %w", refWF.RefPath, err)
+		}
+		if len(refDoc.Content) == 0 {
+			newContent = append(newContent, keyNode, valNode)
+			continue
+		}
+
+		refJobs := mappingValue(refDoc.Content[0], "jobs")
+		if refJobs == nil || refJobs.Kind != yaml.MappingNode {
+			newContent = append(newContent, keyNode, valNode)
+			continue
+		}
+
+		env := inputsToEnv(mappingValue(valNode, "with"), mappingValue(valNode, "secrets"))
+		sourceComment := fmt.Sprintf("from %s (%s)", refWF.RefPath, refWF.SHA)
+
+		namespace := keyNode.Value
+		callerNeeds := mappingValue(valNode, "needs")
+		callerIf := mappingValue(valNode, "if")
+		var entrypointIDs []string
+
+		for j := 0; j+1 < len(refJobs.Content); j += 2 {
+			refKeyNode := refJobs.Content[j]
+			refValNode := refJobs.Content[j+1]
+
+			namespacedKey := &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Tag:   "!!str",
+				Value: fmt.Sprintf("%s__%s", namespace, refKeyNode.Value),
+			}
+			if j == 0 {
+				namespacedKey.HeadComment = sourceComment
+			}
+
+			if len(env) > 0 {
+				setEnv(refValNode, env)
+			}
+
+			intraNeeds := mappingValue(refValNode, "needs")
+			hasIntraNeeds := intraNeeds != nil && !(intraNeeds.Kind == yaml.SequenceNode && len(intraNeeds.Content) == 0)
+			if hasIntraNeeds {
+				namespaceNeeds(intraNeeds, namespace)
+			} else {
+				// No dependency of its own within the reusable workflow,
+				// i.e. this is one of the jobs that ran first under the
+				// call — carry the caller's own needs/if onto it.
+				entrypointIDs = append(entrypointIDs, namespacedKey.Value)
+				if callerNeeds != nil {
+					setNeeds(refValNode, callerNeeds)
+				}
+				if callerIf != nil {
+					setIf(refValNode, callerIf)
+				}
+			}
+
+			newContent = append(newContent, namespacedKey, refValNode)
+		}
+
+		entrypoints[namespace] = entrypointIDs
+	}
+
+	if len(entrypoints) > 0 {
+		remapNeeds(newContent, entrypoints)
+	}
+
+	jobsNode.Content = newContent
+	return len(entrypoints) > 0, nil
+}
+
+// namespaceNeeds rewrites a `needs:` node (scalar or sequence of scalars)
+// referencing job IDs defined within the reusable workflow itself so they
+// point at the namespaced IDs those jobs were inlined under.
+func namespaceNeeds(needsNode *yaml.Node, namespace string) {
+	switch needsNode.Kind {
+	case yaml.ScalarNode:
+		needsNode.Value = fmt.Sprintf("%s__%s", namespace, needsNode.Value)
+	case yaml.SequenceNode:
+		for _, n := range needsNode.Content {
+			if n.Kind == yaml.ScalarNode {
+				n.Value = fmt.Sprintf("%s__%s", namespace, n.Value)
+			}
+		}
+	}
+}
+
+// setNeeds gives jobNode (an inlined entrypoint with no `needs:` of its
+// own) the caller job's `needs:`, preserving whether it was a single
+// dependency or a list.
+func setNeeds(jobNode *yaml.Node, callerNeeds *yaml.Node) {
+	clone := *callerNeeds
+	jobNode.Content = append(jobNode.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "needs"},
+		&clone)
+}
+
+// setIf gives jobNode the caller job's `if:` condition, AND-ing it with any
+// condition the job already carries rather than overwriting it.
+func setIf(jobNode *yaml.Node, callerIf *yaml.Node) {
+	if existing := mappingValue(jobNode, "if"); existing != nil {
+		existing.Value = fmt.Sprintf("${{ (%s) && (%s) }}", stripExprWrapper(existing.Value), stripExprWrapper(callerIf.Value))
+		return
+	}
+
+	jobNode.Content = append(jobNode.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "if"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: callerIf.Value})
+}
+
+// stripExprWrapper strips a `${{ ... }}` wrapper so two if: conditions can
+// be combined into a single expression.
+func stripExprWrapper(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "${{") && strings.HasSuffix(expr, "}}") {
+		return strings.TrimSpace(expr[3 : len(expr)-2])
+	}
+	return expr
+}
+
+// remapNeeds rewrites every `needs:` reference to a job ID that was
+// inlined away so it points at the namespaced entrypoint job(s) that took
+// its place. Run once every job has been inlined, since a `needs:` can
+// reference a job regardless of where it's defined in the map.
+func remapNeeds(jobsContent []*yaml.Node, entrypoints map[string][]string) {
+	for i := 0; i+1 < len(jobsContent); i += 2 {
+		needsNode := mappingValue(jobsContent[i+1], "needs")
+		if needsNode == nil {
+			continue
+		}
+
+		switch needsNode.Kind {
+		case yaml.ScalarNode:
+			ids, ok := entrypoints[needsNode.Value]
+			if !ok || len(ids) == 0 {
+				continue
+			}
+			if len(ids) == 1 {
+				needsNode.Value = ids[0]
+				continue
+			}
+			seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for _, id := range ids {
+				seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id})
+			}
+			*needsNode = *seq
+		case yaml.SequenceNode:
+			newEntries := make([]*yaml.Node, 0, len(needsNode.Content))
+			for _, n := range needsNode.Content {
+				ids, ok := entrypoints[n.Value]
+				if n.Kind != yaml.ScalarNode || !ok {
+					newEntries = append(newEntries, n)
+					continue
+				}
+				for _, id := range ids {
+					newEntries = append(newEntries, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id})
+				}
+			}
+			needsNode.Content = newEntries
+		}
+	}
+}
+
+// inlineCompositeSteps replaces steps that call a composite action we have
+// action.yml content for with that action's own steps, so the document
+// stays self-contained. Actions it couldn't fetch content for (docker
+// actions, unresolved refs) are left as a plain `uses:` step.
+func inlineCompositeSteps(jobsNode *yaml.Node, refs map[string][]Reference) {
+	for i := 0; i+1 < len(jobsNode.Content); i += 2 {
+		stepsNode := mappingValue(jobsNode.Content[i+1], "steps")
+		if stepsNode == nil || stepsNode.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		newSteps := make([]*yaml.Node, 0, len(stepsNode.Content))
+		for _, stepNode := range stepsNode.Content {
+			usesNode := mappingValue(stepNode, "uses")
+			if usesNode == nil || usesNode.Kind != yaml.ScalarNode {
+				newSteps = append(newSteps, stepNode)
+				continue
+			}
+
+			entries, ok := refs[usesNode.Value]
+			if !ok || len(entries) == 0 || entries[0].ActionYAML == "" {
+				newSteps = append(newSteps, stepNode)
+				continue
+			}
+
+			var actionDoc yaml.Node
+			if err := yaml.Unmarshal([]byte(entries[0].ActionYAML), &actionDoc); err != nil || len(actionDoc.Content) == 0 {
+				newSteps = append(newSteps, stepNode)
+				continue
+			}
+
+			compositeSteps := mappingValue(mappingValue(actionDoc.Content[0], "runs"), "steps")
+			if compositeSteps == nil || compositeSteps.Kind != yaml.SequenceNode {
+				newSteps = append(newSteps, stepNode)
+				continue
+			}
+
+			for idx, cs := range compositeSteps.Content {
+				if idx == 0 {
+					cs.HeadComment = fmt.Sprintf("from %s", usesNode.Value)
+				}
+				newSteps = append(newSteps, cs)
+			}
+		}
+
+		stepsNode.Content = newSteps
+	}
+}
+
+// mappingValue looks up key in a YAML mapping node, returning nil if node
+// isn't a mapping or doesn't have the key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// inputsToEnv flattens a job's `with:` and `secrets:` mappings into env
+// vars, one per key, named after the key in upper case. `inputs.*` and
+// `secrets.*` expressions inside the inlined jobs are left as they are.
+func inputsToEnv(with, secrets *yaml.Node) map[string]string {
+	env := make(map[string]string)
+
+	collect := func(n *yaml.Node) {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			env[strings.ToUpper(n.Content[i].Value)] = n.Content[i+1].Value
+		}
+	}
+
+	collect(with)
+	collect(secrets)
+
+	return env
+}
+
+// setEnv merges env into a job node's `env:` mapping, creating it if needed.
+func setEnv(jobNode *yaml.Node, env map[string]string) {
+	if jobNode.Kind != yaml.MappingNode {
+		return
+	}
+
+	existing := mappingValue(jobNode, "env")
+	if existing == nil {
+		jobNode.Content = append(jobNode.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "env"},
+			&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		existing = jobNode.Content[len(jobNode.Content)-1]
+	}
+
+	for k, v := range env {
+		existing.Content = append(existing.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/cli/go-gh/pkg/api"
+	repo "github.com/cli/go-gh/pkg/repository"
+)
+
+type runListItem struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	HeadBranch string    `json:"head_branch"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type runsResponse struct {
+	WorkflowRuns []runListItem `json:"workflow_runs"`
+}
+
+type workflowListItem struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type workflowsResponse struct {
+	Workflows []workflowListItem `json:"workflows"`
+}
+
+var runStatuses = []string{"any", "completed", "in_progress", "queued", "failure"}
+
+// selectRun interactively prompts the user to narrow down by workflow and
+// status, then pick a run to view from the filtered list.
+func selectRun(client api.RESTClient, baseRepo repo.Repository) (string, error) {
+	workflowID, err := promptForWorkflow(client, baseRepo)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := promptForStatus()
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/actions/runs?per_page=30&exclude_pull_requests=true", baseRepo.Owner(), baseRepo.Name())
+	if workflowID != "" {
+		path = fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?per_page=30", baseRepo.Owner(), baseRepo.Name(), workflowID)
+	}
+	if status != "" {
+		path += fmt.Sprintf("&status=%s", status)
+	}
+
+	var result runsResponse
+	if err := client.Get(path, &result); err != nil {
+		return "", fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	if len(result.WorkflowRuns) == 0 {
+		return "", fmt.Errorf("no runs found matching the given filters")
+	}
+
+	options := make([]string, len(result.WorkflowRuns))
+	runIDs := make([]string, len(result.WorkflowRuns))
+	for i, run := range result.WorkflowRuns {
+		options[i] = fmt.Sprintf("%s (%s) %s - %s/%s",
+			run.Name, run.HeadBranch, run.CreatedAt.Format("Jan _2 15:04"), run.Status, run.Conclusion)
+		runIDs[i] = strconv.FormatInt(run.ID, 10)
+	}
+
+	var selected int
+	if err := survey.AskOne(&survey.Select{
+		Message: "Select a run",
+		Options: options,
+	}, &selected); err != nil {
+		return "", fmt.Errorf("could not prompt: %w", err)
+	}
+
+	return runIDs[selected], nil
+}
+
+// promptForWorkflow optionally lets the user narrow the run list down to a
+// single workflow. Returns "" if the user chooses not to filter.
+func promptForWorkflow(client api.RESTClient, baseRepo repo.Repository) (string, error) {
+	path := fmt.Sprintf("repos/%s/%s/actions/workflows?per_page=100", baseRepo.Owner(), baseRepo.Name())
+
+	var result workflowsResponse
+	if err := client.Get(path, &result); err != nil {
+		return "", fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	options := []string{"All workflows"}
+	ids := []string{""}
+	for _, wf := range result.Workflows {
+		options = append(options, wf.Name)
+		ids = append(ids, strconv.FormatInt(wf.ID, 10))
+	}
+
+	var selected int
+	if err := survey.AskOne(&survey.Select{
+		Message: "Filter by workflow",
+		Options: options,
+	}, &selected); err != nil {
+		return "", fmt.Errorf("could not prompt: %w", err)
+	}
+
+	return ids[selected], nil
+}
+
+// promptForStatus optionally lets the user narrow the run list down to a
+// single status. Returns "" if the user chooses not to filter.
+func promptForStatus() (string, error) {
+	var selected string
+	if err := survey.AskOne(&survey.Select{
+		Message: "Filter by status",
+		Options: runStatuses,
+		Default: "any",
+	}, &selected); err != nil {
+		return "", fmt.Errorf("could not prompt: %w", err)
+	}
+
+	if selected == "any" {
+		return "", nil
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cli/cli/v2/pkg/cmd/factory"
+	"github.com/cli/cli/v2/pkg/iostreams"
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+	repo "github.com/cli/go-gh/pkg/repository"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type RenderOptions struct {
+	Path string
+
+	IO *iostreams.IOStreams
+}
+
+func NewCmdRender() *cobra.Command {
+	f := factory.New("1.0.0") // TODO: version
+
+	opts := &RenderOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "render <path-to-workflow.yml>",
+		Short: "Render the effective workflow for a workflow file on disk",
+		Args:  cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			# Preview the effective workflow for a file in the current checkout
+			$ gh effective-workflow render .github/workflows/ci.yml
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Path = args[0]
+
+			return runRender(opts)
+		},
+	}
+
+	return cmd
+}
+
+func runRender(opts *RenderOptions) error {
+	content, err := os.ReadFile(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.Path, err)
+	}
+
+	headSHA, err := gitHeadSHA()
+	if err != nil {
+		return fmt.Errorf("failed to determine current commit: %w", err)
+	}
+
+	repoRoot, err := gitRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to determine repo root: %w", err)
+	}
+
+	var meta struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(content, &meta); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", opts.Path, err)
+	}
+	if meta.Name == "" {
+		meta.Name = filepath.Base(opts.Path)
+	}
+
+	callingWorkflow := Workflow{
+		Name:     meta.Name,
+		Repo:     "local",
+		RefPath:  opts.Path,
+		Filename: filepath.Base(opts.Path),
+		Ref:      "local",
+		SHA:      headSHA,
+		YAML:     string(content),
+	}
+
+	// Find reusable workflows called directly from the local file, so they
+	// can be fetched and displayed alongside it, just like gh effective-workflow
+	// view does for a run.
+	directRefs, err := GetReferences([]Workflow{callingWorkflow}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get references: %w", err)
+	}
+
+	var client api.RESTClient
+	var baseRepo repo.Repository
+
+	wfs := make([]Workflow, 0)
+	for ref, entries := range directRefs {
+		if entries[0].Kind != RefKindReusableWorkflow {
+			continue
+		}
+
+		wf, err := fetchLocalOrRemoteWorkflow(&client, &baseRepo, repoRoot, ref, headSHA)
+		if err != nil {
+			fmt.Fprintln(opts.IO.ErrOut, fmt.Sprintf("warning: failed to resolve %s: %v", ref, err))
+			continue
+		}
+
+		wfs = append(wfs, *wf)
+	}
+
+	allWorkflows := append([]Workflow{callingWorkflow}, wfs...)
+	refs, err := GetReferences(allWorkflows, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get references: %w", err)
+	}
+
+	for ref, entries := range refs {
+		if entries[0].Kind == RefKindReusableWorkflow {
+			continue
+		}
+
+		if err := ensureClient(&client, &baseRepo); err != nil {
+			continue
+		}
+
+		sha, actionYAML, err := resolveActionRef(client, baseRepo, ref, entries[0].Kind, headSHA)
+		if err != nil {
+			fmt.Fprintln(opts.IO.ErrOut, fmt.Sprintf("warning: failed to resolve %s: %v", ref, err))
+			continue
+		}
+
+		for i := range entries {
+			entries[i].ResolvedSHA = sha
+			entries[i].ActionYAML = actionYAML
+		}
+		refs[ref] = entries
+	}
+
+	viewOpts := &ViewOptions{IO: opts.IO}
+
+	return renderText(viewOpts, callingWorkflow, wfs, refs)
+}
+
+// fetchLocalOrRemoteWorkflow resolves a `uses:` reference to a reusable
+// workflow, reading it from disk for `./`-prefixed references and from the
+// GitHub API for `owner/repo/path@ref` references.
+func fetchLocalOrRemoteWorkflow(client *api.RESTClient, baseRepo *repo.Repository, repoRoot, ref, headSHA string) (*Workflow, error) {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		path := filepath.Join(repoRoot, ref)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		return &Workflow{
+			Name:     filepath.Base(ref),
+			Repo:     "local",
+			RefPath:  ref,
+			Filename: filepath.Base(ref),
+			Ref:      "local",
+			SHA:      headSHA,
+			YAML:     string(content),
+		}, nil
+	}
+
+	owner, name, path, refName, err := parseActionRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureClient(client, baseRepo); err != nil {
+		return nil, err
+	}
+
+	referencedRepo, err := repo.Parse(fmt.Sprintf("%s/%s", owner, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse referenced repo: %w", err)
+	}
+
+	content, err := getWorkflowContent(*client, referencedRepo, path, refName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow content: %w", err)
+	}
+
+	return &Workflow{
+		Name:     filepath.Base(path),
+		Repo:     fmt.Sprintf("%s/%s", owner, name),
+		RefPath:  ref,
+		Filename: filepath.Base(path),
+		Ref:      refName,
+		SHA:      refName,
+		YAML:     string(content),
+	}, nil
+}
+
+// ensureClient lazily initializes the REST client and base repo used for
+// resolving references that reach outside the local checkout.
+func ensureClient(client *api.RESTClient, baseRepo *repo.Repository) error {
+	if *client != nil {
+		return nil
+	}
+
+	c, err := gh.RESTClient(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http client: %w", err)
+	}
+	*client = c
+
+	r, err := gh.CurrentRepository()
+	if err != nil {
+		return fmt.Errorf("failed to determine base repo: %w", err)
+	}
+	*baseRepo = r
+
+	return nil
+}
+
+func gitHeadSHA() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitRepoRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -31,6 +31,9 @@ var rootCmd = &cobra.Command{
 
 func main() {
 	rootCmd.AddCommand(NewCmdView())
+	rootCmd.AddCommand(NewCmdRender())
+	rootCmd.AddCommand(NewCmdDiff())
+	rootCmd.AddCommand(NewCmdInline())
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -38,12 +41,17 @@ func main() {
 }
 
 type ViewOptions struct {
-	RunID string
+	RunID    string
+	MaxDepth int
 
-	IO  *iostreams.IOStreams
-	Now func() time.Time
+	IO       *iostreams.IOStreams
+	Now      func() time.Time
+	Exporter cmdutil.Exporter
 }
 
+// jsonFields lists the top-level fields available to --json.
+var jsonFields = []string{"workflows", "references"}
+
 func NewCmdView() *cobra.Command {
 	f := factory.New("1.0.0") // TODO: version
 
@@ -62,18 +70,24 @@ func NewCmdView() *cobra.Command {
 
 			# View a specific run
 			$ gh effective-workflow view 12345
+
+			# Pipe the full reference graph into jq
+			$ gh effective-workflow view 12345 --json workflows,references
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return cmdutil.FlagErrorf("run or job ID required when not running interactively")
-			} else if len(args) > 0 {
+			if len(args) > 0 {
 				opts.RunID = args[0]
+			} else if !opts.IO.CanPrompt() {
+				return cmdutil.FlagErrorf("run ID required when not running interactively")
 			}
 
 			return runView(opts)
 		},
 	}
 
+	cmdutil.AddJSONFlags(cmd, &opts.Exporter, jsonFields)
+	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", 5, "Maximum depth to recurse into nested reusable workflows")
+
 	return cmd
 }
 
@@ -90,26 +104,53 @@ func runView(opts *ViewOptions) error {
 
 	runID := opts.RunID
 
-	run, err := getRun(client, baseRepo, runID)
+	if runID == "" {
+		runID, err = selectRun(client, baseRepo)
+		if err != nil {
+			return fmt.Errorf("failed to select run: %w", err)
+		}
+	}
+
+	result, err := fetchEffectiveWorkflow(opts.IO, client, baseRepo, runID, opts.MaxDepth)
 	if err != nil {
-		return fmt.Errorf("failed to get run: %w", err)
+		return err
+	}
+
+	if opts.Exporter != nil {
+		return renderJSON(opts, result.AllWorkflows, result.References)
 	}
 
-	// Get & show workflow
-	fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().CyanBold("Workflow file for this run\n"))
+	return renderText(opts, result.CallingWorkflow, result.Workflows, result.References)
+}
 
-	workflow, err := getWorkflowByID(client, baseRepo, strconv.FormatInt(run.WorkflowID, 10))
+// EffectiveWorkflowResult is everything fetched and computed for a single
+// run: the calling workflow, every reusable workflow it (transitively)
+// calls, and the full reference graph between them all.
+type EffectiveWorkflowResult struct {
+	Run             *Run
+	CallingWorkflow Workflow
+	Workflows       []Workflow
+	AllWorkflows    []Workflow
+	References      map[string][]Reference
+}
+
+// fetchEffectiveWorkflow fetches the workflow file for runID, every
+// reusable workflow it calls (recursively, up to maxDepth), and resolves
+// the full `uses:` reference graph between them.
+func fetchEffectiveWorkflow(io *iostreams.IOStreams, client api.RESTClient, baseRepo repo.Repository, runID string, maxDepth int) (*EffectiveWorkflowResult, error) {
+	run, err := getRun(client, baseRepo, runID)
 	if err != nil {
-		return fmt.Errorf("failed to get workflow: %w", err)
+		return nil, fmt.Errorf("failed to get run: %w", err)
 	}
 
-	callingWorkflowContent, err := getWorkflowContent(client, baseRepo, workflow.Path, run.HeadBranch)
+	workflow, err := getWorkflowByID(client, baseRepo, strconv.FormatInt(run.WorkflowID, 10))
 	if err != nil {
-		return fmt.Errorf("failed to get workflow content: %w", err)
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
 
-	if err := displayYaml(opts, workflow.Name, workflow.Base(), run.HeadBranch, run.HeadSha, string(callingWorkflowContent), nil); err != nil {
-		return fmt.Errorf("failed to display yaml: %w", err)
+	callingWorkflowContent, err := getWorkflowContent(client, baseRepo, workflow.Path, run.HeadBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow content: %w", err)
 	}
 
 	// Calculate referenced workflows
@@ -117,6 +158,7 @@ func runView(opts *ViewOptions) error {
 
 	callingWorkflow := Workflow{
 		Name:     workflow.Name,
+		Repo:     fmt.Sprintf("%s/%s", baseRepo.Owner(), baseRepo.Name()),
 		RefPath:  workflow.Path,
 		Filename: workflow.Base(),
 		Ref:      run.HeadBranch,
@@ -137,21 +179,22 @@ func runView(opts *ViewOptions) error {
 
 		referencedRepo, err := repo.Parse(nwo)
 		if err != nil {
-			return fmt.Errorf("failed to parse referenced repo: %w", err)
+			return nil, fmt.Errorf("failed to parse referenced repo: %w", err)
 		}
 
 		workflow, err := getWorkflowByID(client, referencedRepo, path)
 		if err != nil {
-			return fmt.Errorf("failed to get workflow: %w", err)
+			return nil, fmt.Errorf("failed to get workflow: %w", err)
 		}
 
 		workflowContent, err := getWorkflowContent(client, referencedRepo, path, ref)
 		if err != nil {
-			return fmt.Errorf("failed to get workflow content: %w", err)
+			return nil, fmt.Errorf("failed to get workflow content: %w", err)
 		}
 
 		wf := Workflow{
 			Name:     workflow.Name,
+			Repo:     nwo,
 			RefPath:  refWF.Path,
 			Filename: workflow.Base(),
 			Ref:      ref,
@@ -166,19 +209,115 @@ func runView(opts *ViewOptions) error {
 	allWorkflows := append([]Workflow{callingWorkflow}, wfs...)
 	refs, err := GetReferences(allWorkflows, run.ReferencedWorkflows)
 	if err != nil {
-		return fmt.Errorf("failed to get references: %w", err)
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+
+	// Walk the graph for reusable workflows not yet fetched (nested
+	// reusable-workflow calls, which run.ReferencedWorkflows doesn't always
+	// flatten for us) until a fixed point or --max-depth is reached.
+	//
+	// visited is keyed by {repo, path, resolvedSHA} rather than the raw
+	// `uses:` string, since the same workflow can be reached via two
+	// different ref strings (e.g. a pinned SHA in one caller and a branch
+	// name in another) that both resolve to the same commit.
+	byRefPath := make(map[string]Workflow, len(allWorkflows))
+	for _, wf := range allWorkflows {
+		byRefPath[wf.RefPath] = wf
+	}
+
+	visited := make(map[string]bool)
+	for _, wf := range allWorkflows {
+		visited[visitKey(wf.Repo, refPathOnly(wf.RefPath), wf.SHA)] = true
+	}
+
+	for depth := 0; depth < maxDepth; depth++ {
+		var newWfs []Workflow
+
+		for ref, entries := range refs {
+			if entries[0].Kind != RefKindReusableWorkflow {
+				continue
+			}
+
+			// Resolve the ref against the repo and SHA of the workflow it
+			// was actually found in, not always baseRepo: a `./`-relative
+			// `uses:` inside a reusable workflow fetched from another repo
+			// lives in that repo, not the run's base repo.
+			sourceRepo := baseRepo
+			containerRef := run.HeadBranch
+			containerSHA := run.HeadSha
+			if container, ok := byRefPath[entries[0].SourceRefPath]; ok {
+				if r, err := repo.Parse(container.Repo); err == nil {
+					sourceRepo = r
+				}
+				containerRef = container.Ref
+				containerSHA = container.SHA
+			}
+
+			repoNWO, path, sha, err := resolveRefIdentity(client, sourceRepo, ref, containerSHA)
+			if err != nil {
+				fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow(fmt.Sprintf("warning: failed to resolve nested workflow %s: %v", ref, err)))
+				continue
+			}
+
+			key := visitKey(repoNWO, path, sha)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			wf, err := fetchReferencedWorkflow(client, sourceRepo, containerRef, ref)
+			if err != nil {
+				fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow(fmt.Sprintf("warning: failed to resolve nested workflow %s: %v", ref, err)))
+				continue
+			}
+			if sha != "" {
+				wf.SHA = sha
+			}
+
+			newWfs = append(newWfs, *wf)
+			byRefPath[wf.RefPath] = *wf
+		}
+
+		if len(newWfs) == 0 {
+			break
+		}
+
+		wfs = append(wfs, newWfs...)
+		allWorkflows = append(allWorkflows, newWfs...)
+
+		refs, err = GetReferences(allWorkflows, run.ReferencedWorkflows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get references: %w", err)
+		}
 	}
 
-	// Output
-	for _, wf := range wfs {
-		fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().CyanBold("Called reusable workflow file\n"))
+	// Resolve action references (reusable workflows are already resolved
+	// above via run.ReferencedWorkflows).
+	for ref, entries := range refs {
+		if entries[0].Kind == RefKindReusableWorkflow {
+			continue
+		}
+
+		sha, actionYAML, err := resolveActionRef(client, baseRepo, ref, entries[0].Kind, run.HeadBranch)
+		if err != nil {
+			fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow(fmt.Sprintf("warning: failed to resolve %s: %v", ref, err)))
+			continue
+		}
 
-		if err := displayYaml(opts, wf.Name, wf.Filename, wf.Ref, wf.SHA, wf.YAML, refs[wf.RefPath]); err != nil {
-			return fmt.Errorf("failed to display yaml: %w", err)
+		for i := range entries {
+			entries[i].ResolvedSHA = sha
+			entries[i].ActionYAML = actionYAML
 		}
+		refs[ref] = entries
 	}
 
-	return nil
+	return &EffectiveWorkflowResult{
+		Run:             run,
+		CallingWorkflow: callingWorkflow,
+		Workflows:       wfs,
+		AllWorkflows:    allWorkflows,
+		References:      refs,
+	}, nil
 }
 
 type ReferencedWorkflow struct {
@@ -285,7 +424,11 @@ func displayYaml(opts *ViewOptions, name, fileName, ref, sha, yaml string, refs
 			// fmt.Fprintf(out, "- %s\t%s:\t%s\n", cs.Gray(ref.SourceFilename), cs.Gray(strconv.Itoa(ref.SourceLineNo)), line)
 			t.AddField(cs.Gray(ref.SourceFilename))
 			t.AddField(cs.Gray(fmt.Sprintf("%4d", ref.SourceLineNo)))
+			t.AddField(cs.Gray(string(ref.Kind)))
 			t.AddField(line)
+			if ref.ResolvedSHA != "" {
+				t.AddField(cs.Gray(ref.ResolvedSHA))
+			}
 			t.EndRow()
 		}
 
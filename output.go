@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// renderText prints the calling workflow followed by every reusable
+// workflow it calls, each annotated with the references found for it. This
+// is the original `gh effective-workflow view` output format.
+func renderText(opts *ViewOptions, callingWorkflow Workflow, wfs []Workflow, refs map[string][]Reference) error {
+	fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().CyanBold("Workflow file for this run\n"))
+	if err := displayYaml(opts, callingWorkflow.Name, callingWorkflow.Filename, callingWorkflow.Ref, callingWorkflow.SHA, callingWorkflow.YAML, workflowRefs(callingWorkflow, refs)); err != nil {
+		return fmt.Errorf("failed to display yaml: %w", err)
+	}
+
+	for _, wf := range wfs {
+		fmt.Fprintln(opts.IO.Out, opts.IO.ColorScheme().CyanBold("Called reusable workflow file\n"))
+
+		if err := displayYaml(opts, wf.Name, wf.Filename, wf.Ref, wf.SHA, wf.YAML, workflowRefs(wf, refs)); err != nil {
+			return fmt.Errorf("failed to display yaml: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// workflowRefs collects every reference relevant to wf: the inbound edges
+// (call sites that invoke wf via refs[wf.RefPath]) plus the outbound action
+// references found inside wf's own steps (refs keyed by the `uses:` values
+// its steps point at, filtered down to the ones sourced from wf itself).
+func workflowRefs(wf Workflow, refs map[string][]Reference) []Reference {
+	result := append([]Reference{}, refs[wf.RefPath]...)
+
+	for _, entries := range refs {
+		for _, ref := range entries {
+			if ref.Kind != RefKindReusableWorkflow && ref.SourceRefPath == wf.RefPath {
+				result = append(result, ref)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SourceFilename != result[j].SourceFilename {
+			return result[i].SourceFilename < result[j].SourceFilename
+		}
+		return result[i].SourceLineNo < result[j].SourceLineNo
+	})
+
+	return result
+}
+
+// WorkflowJSON is the --json representation of a single fetched workflow.
+type WorkflowJSON struct {
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	YAML string `json:"yaml"`
+}
+
+// ReferenceJSON is the --json representation of a single edge in the
+// reference graph: workflow/action at from_file:from_line uses to_ref_path,
+// which resolved to to_resolved_sha (when it could be determined).
+type ReferenceJSON struct {
+	FromFile      string `json:"from_file"`
+	FromLine      int    `json:"from_line"`
+	ToRefPath     string `json:"to_ref_path"`
+	ToResolvedSHA string `json:"to_resolved_sha,omitempty"`
+	Kind          string `json:"kind"`
+}
+
+// EffectiveWorkflow is the top-level --json document: every fetched
+// workflow plus the full reference graph between them.
+type EffectiveWorkflow struct {
+	Workflows  []WorkflowJSON  `json:"workflows"`
+	References []ReferenceJSON `json:"references"`
+}
+
+// renderJSON writes the effective workflow as structured JSON via the
+// exporter set up by cmdutil.AddJSONFlags, so it honors --json/--jq/--template.
+func renderJSON(opts *ViewOptions, workflows []Workflow, refs map[string][]Reference) error {
+	doc := EffectiveWorkflow{
+		Workflows:  make([]WorkflowJSON, 0, len(workflows)),
+		References: make([]ReferenceJSON, 0),
+	}
+
+	for _, wf := range workflows {
+		doc.Workflows = append(doc.Workflows, WorkflowJSON{
+			Name: wf.Name,
+			Repo: wf.Repo,
+			Path: wf.RefPath,
+			Ref:  wf.Ref,
+			SHA:  wf.SHA,
+			YAML: wf.YAML,
+		})
+	}
+
+	for to, entries := range refs {
+		for _, ref := range entries {
+			doc.References = append(doc.References, ReferenceJSON{
+				FromFile:      ref.SourceFilename,
+				FromLine:      ref.SourceLineNo,
+				ToRefPath:     to,
+				ToResolvedSHA: ref.ResolvedSHA,
+				Kind:          string(ref.Kind),
+			})
+		}
+	}
+
+	sort.Slice(doc.References, func(i, j int) bool {
+		a, b := doc.References[i], doc.References[j]
+		if a.FromFile != b.FromFile {
+			return a.FromFile < b.FromFile
+		}
+		if a.FromLine != b.FromLine {
+			return a.FromLine < b.FromLine
+		}
+		return a.ToRefPath < b.ToRefPath
+	})
+
+	return opts.Exporter.Write(opts.IO, doc)
+}
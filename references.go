@@ -8,7 +8,10 @@ import (
 )
 
 type Workflow struct {
-	Name     string
+	Name string
+	// Repo is the "owner/name" the workflow lives in, or "local" when read
+	// straight from disk.
+	Repo     string
 	RefPath  string
 	Filename string
 	Ref      string
@@ -16,50 +19,127 @@ type Workflow struct {
 	YAML     string
 }
 
+// RefKind classifies what a `uses:` value points at.
+type RefKind string
+
+const (
+	RefKindReusableWorkflow  RefKind = "reusable-workflow"
+	RefKindMarketplaceAction RefKind = "marketplace-action"
+	RefKindLocalAction       RefKind = "local-action"
+	RefKindDockerAction      RefKind = "docker-action"
+)
+
 type Reference struct {
 	SourceFilename string
 	SourceLine     string
 	SourceLineNo   int
+
+	// SourceRepo is the "owner/name" (or "local") of the workflow that
+	// contains this reference.
+	SourceRepo string
+	// SourceRefPath is the RefPath of the workflow that contains this
+	// reference.
+	SourceRefPath string
+
+	// Kind classifies the `uses:` target this reference points at.
+	Kind RefKind
+
+	// ResolvedSHA is the commit SHA the ref currently resolves to, when it
+	// could be looked up. Empty for docker refs.
+	ResolvedSHA string
+
+	// ActionYAML holds the fetched action.yml/action.yaml content for
+	// marketplace and local action refs, when available.
+	ActionYAML string
 }
 
 // Parsing
+//
+// Modeled loosely on act's pkg/model/workflow.go: a job is either a call to
+// a reusable workflow (`uses:` on the job itself) or a list of steps, each
+// of which may itself be an action reference.
 type WorkflowNode struct {
 	Jobs map[string]Job `yaml:"jobs"`
 }
 
 type Job struct {
-	Uses yaml.Node `yaml:"uses"`
+	Uses  yaml.Node `yaml:"uses"`
+	Steps []Step    `yaml:"steps"`
 }
 
+type Step struct {
+	Uses yaml.Node         `yaml:"uses"`
+	With map[string]string `yaml:"with"`
+	Run  string            `yaml:"run"`
+	If   string            `yaml:"if"`
+}
+
+// classifyRef determines what kind of reference a `uses:` value is.
+func classifyRef(ref string) RefKind {
+	path := ref
+	if i := strings.Index(ref, "@"); i >= 0 {
+		path = ref[:i]
+	}
+
+	switch {
+	case strings.HasPrefix(ref, "docker://"):
+		return RefKindDockerAction
+	case strings.Contains(path, "/.github/workflows/") || strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml"):
+		return RefKindReusableWorkflow
+	case strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../"):
+		return RefKindLocalAction
+	default:
+		return RefKindMarketplaceAction
+	}
+}
+
+// GetReferences walks every job (and job step) of each workflow and returns
+// a graph of every `uses:` target found, keyed by the raw reference string.
+// This covers both reusable-workflow calls and the action references used
+// by individual steps.
 func GetReferences(workflows []Workflow, references []ReferencedWorkflow) (map[string][]Reference, error) {
 	result := make(map[string][]Reference)
 
+	addRef := func(workflow Workflow, node yaml.Node) error {
+		if node.IsZero() {
+			return nil
+		}
+
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+			return fmt.Errorf("unexpected node type for uses: %v", node.Kind)
+		}
+
+		workflowRef := node.Value
+		result[workflowRef] = append(result[workflowRef], Reference{
+			SourceFilename: workflow.Filename,
+			SourceLine:     strings.Split(workflow.YAML, "\n")[node.Line-1], // 🙀
+			SourceLineNo:   node.Line,
+			SourceRepo:     workflow.Repo,
+			SourceRefPath:  workflow.RefPath,
+			Kind:           classifyRef(workflowRef),
+		})
+
+		return nil
+	}
+
 	for _, workflow := range workflows {
 		var wfNode WorkflowNode
 		if err := yaml.Unmarshal([]byte(workflow.YAML), &wfNode); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal yaml: %w", err)
 		}
 
-		// Check each job for references to other workflows
-		if wfNode.Jobs != nil {
-			for _, job := range wfNode.Jobs {
-				if !job.Uses.IsZero() {
-					if job.Uses.Kind != yaml.ScalarNode || job.Uses.Tag != "!!str" {
-						return nil, fmt.Errorf("unexpected node type for uses: %v", job.Uses.Kind)
-					}
-
-					workflowRef := job.Uses.Value
-					workflowRefLine := job.Uses.Line
-
-					if _, ok := result[workflowRef]; !ok {
-						result[workflowRef] = []Reference{}
-					}
-
-					result[workflowRef] = append(result[workflowRef], Reference{
-						SourceFilename: workflow.Filename,
-						SourceLine:     strings.Split(workflow.YAML, "\n")[workflowRefLine-1], // 🙀
-						SourceLineNo:   job.Uses.Line,
-					})
+		if wfNode.Jobs == nil {
+			continue
+		}
+
+		for _, job := range wfNode.Jobs {
+			if err := addRef(workflow, job.Uses); err != nil {
+				return nil, err
+			}
+
+			for _, step := range job.Steps {
+				if err := addRef(workflow, step.Uses); err != nil {
+					return nil, err
 				}
 			}
 		}